@@ -0,0 +1,109 @@
+/*
+Copyright 2019 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package jibjson implements the small framing protocol Jib plugins use to emit structured data
+// on stdout: a "BEGIN JIB JSON[: KIND/VERSION]" marker line followed by a single line of JSON.
+// Each kind/version pair (e.g. "SYNCMAP/1") has its own registered decoder, so callers get a
+// typed struct back instead of having to regex their way through plugin output, and a new jib
+// feature can add a kind or bump a version without any caller having to change its scanning.
+//
+// Only the SYNCMAP kind is registered today, backing the sync-map parsing in sync.go. Jib also
+// emits an IMAGE JSON frame and skaffold parses build definitions separately; neither of those
+// parsers lives in this tree, so routing them through this package is left for whoever owns
+// that code to do by registering an "IMAGE/1" (etc.) decoder the same way syncmap.go does.
+package jibjson
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// marker matches a framing header line, capturing the kind/version suffix when present. The
+// suffix was introduced in Jib 2.0.0; older plugins emit a bare "BEGIN JIB JSON" marker.
+var marker = regexp.MustCompile(`^BEGIN JIB JSON(?:: (\w+)/(\d+))?\s*$`)
+
+// maxPayloadLineSize bounds the single JSON payload line bufio.Scanner will buffer. Jib emits an
+// entire sync map (or image JSON) as one line, which for a project with hundreds of
+// direct/generated entries comfortably exceeds the scanner's 64KiB default.
+const maxPayloadLineSize = 50 * 1024 * 1024 // 50MiB
+
+// Decoder decodes a single framed JSON payload into a concrete, kind-specific type.
+type Decoder func(payload []byte) (interface{}, error)
+
+var decoders = map[string]Decoder{}
+
+// defaultVersions maps a kind to the version assumed when a plugin emits a bare "BEGIN JIB JSON"
+// marker with no explicit kind/version, for compatibility with Jib versions older than 2.0.0.
+var defaultVersions = map[string]int{}
+
+// Register associates a decoder with a "<kind>/<version>" pair, e.g. "SYNCMAP/1". Call it from a
+// package init() function. If isDefault is true, a bare "BEGIN JIB JSON" marker (no kind/version
+// suffix) is treated as this kind/version.
+func Register(kind string, version int, isDefault bool, d Decoder) {
+	decoders[frameKey(kind, version)] = d
+	if isDefault {
+		defaultVersions[kind] = version
+	}
+}
+
+func frameKey(kind string, version int) string {
+	return fmt.Sprintf("%s/%d", kind, version)
+}
+
+// Scan looks for the first "BEGIN JIB JSON" marker of the given kind in stdout and decodes the
+// JSON payload on the following line using the decoder registered for its kind/version.
+func Scan(kind string, stdout []byte) (interface{}, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(stdout))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxPayloadLineSize)
+	for scanner.Scan() {
+		line := scanner.Text()
+		m := marker.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		frameKind, version := kind, defaultVersions[kind]
+		if m[1] != "" {
+			frameKind = m[1]
+			v, err := strconv.Atoi(m[2])
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid JIB JSON version in marker %q", line)
+			}
+			version = v
+		}
+		if frameKind != kind {
+			continue
+		}
+
+		if !scanner.Scan() {
+			return nil, errors.Errorf("no JSON payload after %q marker", line)
+		}
+		decoder, ok := decoders[frameKey(frameKind, version)]
+		if !ok {
+			return nil, errors.Errorf("no JIB JSON decoder registered for %s/%d", frameKind, version)
+		}
+		return decoder(scanner.Bytes())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "scanning for JIB JSON marker")
+	}
+	return nil, errors.Errorf("failed to find a BEGIN JIB JSON marker for kind %s", kind)
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2019 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jibjson
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// KindSyncMap identifies the frame kind emitted by Jib's `_skaffoldSyncMap` task/goal.
+const KindSyncMap = "SYNCMAP"
+
+// SyncMapV1 is the payload of a "SYNCMAP/1" frame (and of the unversioned frames emitted by Jib
+// versions older than 2.0.0, which this package also decodes as SYNCMAP/1).
+type SyncMapV1 struct {
+	Direct    []SyncMapEntry `json:"direct"`
+	Generated []SyncMapEntry `json:"generated"`
+}
+
+// SyncMapEntry is a single source-to-destination mapping within a SyncMapV1 payload.
+type SyncMapEntry struct {
+	Src  string `json:"src"`
+	Dest string `json:"dest"`
+}
+
+func init() {
+	Register(KindSyncMap, 1, true, decodeSyncMapV1)
+}
+
+func decodeSyncMapV1(payload []byte) (interface{}, error) {
+	// Jib escapes backslashes inconsistently in this payload (e.g. Windows paths); double them
+	// up before unmarshalling so encoding/json doesn't choke on a stray escape sequence.
+	payload = bytes.Replace(payload, []byte(`\`), []byte(`\\`), -1)
+
+	var sm SyncMapV1
+	if err := json.Unmarshal(payload, &sm); err != nil {
+		return nil, err
+	}
+	return &sm, nil
+}
@@ -19,16 +19,16 @@ package jib
 import (
 	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
+	"crypto/sha256"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"time"
 
 	"github.com/pkg/errors"
 
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build/jib/jibjson"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/filemon"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
@@ -36,33 +36,53 @@ import (
 
 var syncLists = map[projectKey]SyncMap{}
 
+// defaultDigestFileSizeThreshold is the file size above which we skip hashing a file and fall
+// back to comparing mtimes, since the read cost starts to outweigh the false-positive risk.
+// Overridable per-artifact via latest.JibArtifact.SyncHashFileSizeThreshold.
+const defaultDigestFileSizeThreshold = 1 << 20 // 1MiB
+
 type SyncMap map[string]SyncEntry
 
 type SyncEntry struct {
 	Dest     []string
 	FileTime time.Time
+	FileSize int64
+	// Digest is a SHA-256 content digest, computed lazily alongside the stat done to populate
+	// FileTime/FileSize. It is left nil for files over the digest size threshold, in which case
+	// comparisons fall back to FileTime.
+	Digest   []byte
 	IsDirect bool
 }
 
-type JSONSyncMap struct {
-	Direct    []JSONSyncEntry `json:"direct"`
-	Generated []JSONSyncEntry `json:"generated"`
-}
-
-type JSONSyncEntry struct {
-	Src  string `json:"src"`
-	Dest string `json:"dest"`
-}
+// JSONSyncMap and JSONSyncEntry are kept as aliases of their jibjson equivalents so existing
+// callers of this package don't need to change; the jib JSON framing itself now lives in
+// jibjson, which also backs future sync-map/image-JSON/build-definition parsing.
+type JSONSyncMap = jibjson.SyncMapV1
+type JSONSyncEntry = jibjson.SyncMapEntry
 
+// InitSync seeds syncLists for the project, preferring a valid on-disk cache over invoking the
+// Jib plugin so that a warm `skaffold dev` restart doesn't pay for a JVM boot on the first change.
 func InitSync(ctx context.Context, workspace string, a *latest.JibArtifact) error {
+	key := getProjectKey(workspace, a)
+	buildFiles := GetBuildDefinitions(workspace, a)
+
+	if cached, err := loadSyncMapCache(key, buildFiles); err == nil && cached != nil {
+		syncLists[key] = *cached
+		return nil
+	}
+
 	syncMap, err := getSyncMapFunc(ctx, workspace, a)
 	if err != nil {
 		return err
 	}
-	syncLists[getProjectKey(workspace, a)] = *syncMap
-	return nil
+	syncLists[key] = *syncMap
+	return saveSyncMapCache(key, buildFiles, *syncMap)
 }
 
+// GetSyncDiff calculates a sync diff against the last known sync map for the project, returning
+// toCopy and toDelete maps of absolute source path to container destinations. The caller is
+// responsible for applying the diff against the running container (copying files in, and
+// `kubectl exec`-ing an `rm -f` for deletions).
 // returns toCopy, toDelete, error
 func GetSyncDiff(ctx context.Context, workspace string, a *latest.JibArtifact, e filemon.Events) (map[string][]string, map[string][]string, error) {
 	// if anything that was modified was a buildfile, do NOT sync, do a rebuild
@@ -79,17 +99,36 @@ func GetSyncDiff(ctx context.Context, workspace string, a *latest.JibArtifact, e
 		}
 	}
 
-	// no deletions
-	if len(e.Deleted) != 0 {
-		// change into logging
-		fmt.Println("Deletions are not supported by jib auto sync at the moment")
-		return nil, nil, nil
-	}
-
 	currSyncMap := syncLists[getProjectKey(workspace, a)]
 
-	// if all files are modified and direct, we don't need to build anything
-	if len(e.Deleted) == 0 && len(e.Added) == 0 {
+	// deleted files are mapped to their last known container destination; deleting a generated
+	// (non-direct) entry forces a rebuild since we can't be sure a generated file's destination is
+	// still valid without re-running the build, and deleted files that aren't in the syncmap at
+	// all are ignored. Evictions from currSyncMap are
+	// only applied once we know none of the deletions force a rebuild, so a non-direct deletion
+	// doesn't discard the toDelete entries already found for earlier, direct ones.
+	toDelete := make(map[string][]string)
+	for _, f := range e.Deleted {
+		f, err := toAbs(f)
+		if err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+		val, ok := currSyncMap[f]
+		if !ok {
+			continue
+		}
+		if !val.IsDirect {
+			return nil, nil, nil
+		}
+		toDelete[f] = val.Dest
+	}
+	for f := range toDelete {
+		delete(currSyncMap, f)
+	}
+
+	// if all files are modified and direct, we don't need to build anything; deletions were
+	// already resolved above (a non-direct deletion would have forced a rebuild by now)
+	if len(e.Added) == 0 {
 		matches := make(map[string][]string)
 		for _, f := range e.Modified {
 			f, err := toAbs(f)
@@ -101,19 +140,21 @@ func GetSyncDiff(ctx context.Context, workspace string, a *latest.JibArtifact, e
 					break
 				}
 				matches[f] = val.Dest
-				// update file times in sync entries for these direct files, in case all matches are direct and we don't update the syncmap using a build
-				infog, err := os.Stat(f)
+				// refresh the cached entry for these direct files (time, size and digest), in case
+				// all matches are direct and we don't update the syncmap using a build; otherwise
+				// the next syncmap diff would compare a fresh digest against this stale one and
+				// needlessly re-copy a file that was already synced here
+				entry, err := newSyncEntry(f, val.Dest, true, digestFileSizeThreshold(a))
 				if err != nil {
-					return nil, nil, errors.Wrap(err, "could not obtain file mod time")
+					return nil, nil, err
 				}
-				val.FileTime = infog.ModTime()
-				currSyncMap[f] = val
+				currSyncMap[f] = entry
 			} else {
 				break
 			}
 		}
 		if len(matches) == len(e.Modified) {
-			return matches, nil, nil
+			return matches, toDelete, nil
 		}
 	}
 
@@ -123,16 +164,16 @@ func GetSyncDiff(ctx context.Context, workspace string, a *latest.JibArtifact, e
 		return nil, nil, err
 	}
 	syncLists[getProjectKey(workspace, a)] = *nextSyncMap
-
-	fmt.Println("curr", currSyncMap)
-	fmt.Println("next", nextSyncMap)
+	if err := saveSyncMapCache(getProjectKey(workspace, a), buildFiles, *nextSyncMap); err != nil {
+		return nil, nil, err
+	}
 
 	toCopy := make(map[string][]string)
-	// calculate the diff of the syncmaps
+	// calculate the diff of the syncmaps, preferring a content digest comparison over mtime so
+	// that a `git checkout` or a build regenerating identical output doesn't trigger a copy
 	for k, v := range *nextSyncMap {
 		if curr, ok := currSyncMap[k]; ok {
-			if v.FileTime != curr.FileTime {
-				// file updated
+			if entryChanged(curr, v) {
 				toCopy[k] = v.Dest
 			}
 		} else {
@@ -141,7 +182,7 @@ func GetSyncDiff(ctx context.Context, workspace string, a *latest.JibArtifact, e
 		}
 	}
 
-	return toCopy, nil, nil
+	return toCopy, toDelete, nil
 }
 
 // for testing
@@ -156,7 +197,7 @@ func getSyncMap(ctx context.Context, workspace string, artifact *latest.JibArtif
 		return nil, errors.WithStack(err)
 	}
 
-	sm, err := getSyncMapFromSystem(cmd)
+	sm, err := getSyncMapFromSystem(cmd, digestFileSizeThreshold(artifact))
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -179,52 +220,96 @@ func getSyncMapCommand(ctx context.Context, workspace string, artifact *latest.J
 	}
 }
 
-func getSyncMapFromSystem(cmd *exec.Cmd) (*SyncMap, error) {
-	jsm := JSONSyncMap{}
+func getSyncMapFromSystem(cmd *exec.Cmd, threshold int64) (*SyncMap, error) {
 	stdout, err := util.RunCmdOut(cmd)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get Jib sync map")
 	}
 
-	// To parse the output, search for "BEGIN JIB JSON", then unmarshal the next line into the pathMap struct.
-	// Syncmap is transitioning to "BEGIN JIB JSON: SYNCMAP/1" starting in jib 2.0.0
-	// perhaps this feature should only be included from 2.0.0 onwards? And we generally avoid this?
-	matches := regexp.MustCompile(`BEGIN JIB JSON(?:: SYNCMAP/1)?\r?\n({.*})`).FindSubmatch(stdout)
-	if len(matches) == 0 {
-		return nil, errors.New("failed to get Jib Sync data")
-	}
-
-	line := bytes.Replace(matches[1], []byte(`\`), []byte(`\\`), -1)
-	if err := json.Unmarshal(line, &jsm); err != nil {
-		return nil, errors.WithStack(err)
+	frame, err := jibjson.Scan(jibjson.KindSyncMap, stdout)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get Jib sync data")
 	}
+	jsm := frame.(*jibjson.SyncMapV1)
 
 	sm := make(SyncMap)
 	for _, de := range jsm.Direct {
-		info, err := os.Stat(de.Src)
+		entry, err := newSyncEntry(de.Src, []string{de.Dest}, true, threshold)
 		if err != nil {
-			return nil, errors.Wrap(err, "could not obtain file mod time")
-		}
-		sm[de.Src] = SyncEntry{
-			Dest:     []string{de.Dest},
-			FileTime: info.ModTime(),
-			IsDirect: true,
+			return nil, err
 		}
+		sm[de.Src] = entry
 	}
 	for _, ge := range jsm.Generated {
-		info, err := os.Stat(ge.Src)
+		entry, err := newSyncEntry(ge.Src, []string{ge.Dest}, false, threshold)
 		if err != nil {
-			return nil, errors.Wrap(err, "could not obtain file mod time")
-		}
-		sm[ge.Src] = SyncEntry{
-			Dest:     []string{ge.Dest},
-			FileTime: info.ModTime(),
-			IsDirect: false,
+			return nil, err
 		}
+		sm[ge.Src] = entry
 	}
 	return &sm, nil
 }
 
+// newSyncEntry stats src and, for files at or under threshold, also hashes its contents so later
+// diffs can compare digests instead of relying solely on FileTime.
+func newSyncEntry(src string, dest []string, isDirect bool, threshold int64) (SyncEntry, error) {
+	info, err := os.Stat(src)
+	if err != nil {
+		return SyncEntry{}, errors.Wrap(err, "could not obtain file mod time")
+	}
+
+	entry := SyncEntry{
+		Dest:     dest,
+		FileTime: info.ModTime(),
+		FileSize: info.Size(),
+		IsDirect: isDirect,
+	}
+	if entry.FileSize <= threshold {
+		digest, err := fileDigest(src)
+		if err != nil {
+			return SyncEntry{}, err
+		}
+		entry.Digest = digest
+	}
+	return entry, nil
+}
+
+func fileDigest(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open file to compute digest")
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, errors.Wrap(err, "could not read file to compute digest")
+	}
+	return h.Sum(nil), nil
+}
+
+// digestFileSizeThreshold returns the largest file size, in bytes, that getSyncMapFromSystem will
+// still hash rather than falling back to mtime comparisons.
+func digestFileSizeThreshold(a *latest.JibArtifact) int64 {
+	if a != nil && a.SyncHashFileSizeThreshold > 0 {
+		return a.SyncHashFileSizeThreshold
+	}
+	return defaultDigestFileSizeThreshold
+}
+
+// entryChanged reports whether next differs from curr. A size difference always counts as a
+// change; for equal sizes it compares digests when both entries have one (i.e. both are at or
+// under the digest size threshold), falling back to comparing FileTime otherwise.
+func entryChanged(curr, next SyncEntry) bool {
+	if curr.FileSize != next.FileSize {
+		return true
+	}
+	if curr.Digest != nil && next.Digest != nil {
+		return !bytes.Equal(curr.Digest, next.Digest)
+	}
+	return !curr.FileTime.Equal(next.FileTime)
+}
+
 func toAbs(f string) (string, error) {
 	if !filepath.IsAbs(f) {
 		af, err := filepath.Abs(f)
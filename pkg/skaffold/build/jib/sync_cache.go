@@ -0,0 +1,130 @@
+/*
+Copyright 2019 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/config"
+)
+
+// persistedSyncMap is what we serialize to the on-disk sync map cache: the sync map itself,
+// keyed to a digest of the build files that produced it so a stale cache entry (e.g. because
+// pom.xml or build.gradle changed since the cache was written) is detected and ignored.
+type persistedSyncMap struct {
+	BuildFilesDigest string  `json:"buildFilesDigest"`
+	SyncMap          SyncMap `json:"syncMap"`
+}
+
+// syncCacheDir returns the directory skaffold persists Jib sync maps under, alongside the rest
+// of skaffold's on-disk caches.
+func syncCacheDir() (string, error) {
+	dir, err := config.GetSkaffoldCacheDir()
+	if err != nil {
+		return "", errors.Wrap(err, "getting skaffold cache directory")
+	}
+	return filepath.Join(dir, "jib-sync-maps"), nil
+}
+
+func syncCacheFile(key projectKey) (string, error) {
+	dir, err := syncCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(dir, hex.EncodeToString(sum[:])+".json"), nil
+}
+
+// buildFilesDigest hashes the path, size and mtime of each of the project's build definition
+// files, so a cached sync map is invalidated as soon as any of them change.
+func buildFilesDigest(buildFiles []string) (string, error) {
+	h := sha256.New()
+	for _, f := range buildFiles {
+		info, err := os.Stat(f)
+		if err != nil {
+			return "", errors.Wrapf(err, "stating build file %s", f)
+		}
+		fmt.Fprintf(h, "%s:%d:%d\n", f, info.Size(), info.ModTime().UnixNano())
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// loadSyncMapCache returns the persisted sync map for key, or (nil, nil) if there is no cache
+// entry, or the cache entry no longer matches the current state of buildFiles.
+func loadSyncMapCache(key projectKey, buildFiles []string) (*SyncMap, error) {
+	path, err := syncCacheFile(key)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "reading jib sync map cache")
+	}
+
+	var persisted persistedSyncMap
+	if err := json.Unmarshal(b, &persisted); err != nil {
+		return nil, errors.Wrap(err, "parsing jib sync map cache")
+	}
+
+	digest, err := buildFilesDigest(buildFiles)
+	if err != nil {
+		return nil, err
+	}
+	if digest != persisted.BuildFilesDigest {
+		return nil, nil
+	}
+	return &persisted.SyncMap, nil
+}
+
+// saveSyncMapCache persists sm to disk for key, tagged with a digest of buildFiles so a later
+// loadSyncMapCache call can tell whether the cache is still fresh.
+func saveSyncMapCache(key projectKey, buildFiles []string, sm SyncMap) error {
+	digest, err := buildFilesDigest(buildFiles)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(persistedSyncMap{BuildFilesDigest: digest, SyncMap: sm})
+	if err != nil {
+		return errors.Wrap(err, "marshalling jib sync map cache")
+	}
+
+	dir, err := syncCacheDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.Wrap(err, "creating jib sync map cache dir")
+	}
+
+	path, err := syncCacheFile(key)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(ioutil.WriteFile(path, b, 0644), "writing jib sync map cache")
+}
@@ -0,0 +1,37 @@
+/*
+Copyright 2019 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package latest
+
+// JibArtifact builds images using the
+// [Jib plugins for Maven and Gradle](https://github.com/GoogleContainerTools/jib).
+type JibArtifact struct {
+	// Project selects which sub-project to build for multi-module builds.
+	Project string `yaml:"project,omitempty"`
+
+	// Type the Jib builder type; normally determined automatically. Valid types are
+	// `maven`: for Maven. `gradle`: for Gradle.
+	Type string `yaml:"type,omitempty" yamltags:"oneOf=builder"`
+
+	// Args additional build flags passed to Jib.
+	// For example: `["--no-build-cache"]`.
+	Args []string `yaml:"args,omitempty"`
+
+	// SyncHashFileSizeThreshold is the largest file size, in bytes, for which Jib auto-sync will
+	// hash file contents to detect changes instead of relying on file modification time. Files
+	// larger than this threshold fall back to an mtime comparison. Defaults to 1MiB.
+	SyncHashFileSizeThreshold int64 `yaml:"syncHashFileSizeThreshold,omitempty"`
+}